@@ -26,6 +26,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/datastore"
 	"cloud.google.com/go/datastore/apiv1/datastorepb"
@@ -40,6 +42,7 @@ type (
 		client     *datastore.Client
 		projectId  string
 		databaseId string
+		namespace  string
 	}
 
 	// QueryBuilder provides a fluent interface for constructing and executing
@@ -52,14 +55,105 @@ type (
 		db          *DB
 		query       *datastore.Query
 		kind        string
+		namespace   string
 		limit       int
 		usingOffset bool
 		usingCursor bool
+		filter      Filter
 	}
 
 	// FilterOperator represents valid comparison operators for Datastore queries.
 	// Use the predefined constants (OpEqual, OpGreater, etc.) for type safety.
 	FilterOperator string
+
+	// Filter represents a node in a composable filter tree, as produced by
+	// FilterBuilder. It can be a single property comparison or an And/Or
+	// combination of other Filters, and is installed on a query with
+	// WithFilterEntity.
+	Filter = datastore.EntityFilter
+
+	// FilterBuilder constructs composable Filter trees for a QueryBuilder's
+	// kind. Obtain one via QueryBuilder.FilterBuilder.
+	//
+	// Note: there is no Ancestor method. Datastore's HAS_ANCESTOR operator
+	// isn't one of the PropertyFilter comparison operators the client
+	// accepts by name (see FilterOperator), so an ancestor constraint can't
+	// be composed inside a filter tree alongside And/Or. Use
+	// QueryBuilder.WithAncestorKey or WithAncestorPath instead, which apply
+	// the ancestor constraint directly on the query.
+	FilterBuilder struct {
+		kind      string
+		namespace string
+	}
+
+	// Tx represents a running Datastore transaction, as passed to the
+	// callback given to DB.RunInTransaction. Use TxQuery to build queries
+	// and writes that are scoped to it.
+	Tx struct {
+		tx      *datastore.Transaction
+		db      *DB
+		context context.Context
+	}
+
+	// TxOption configures a transaction started with DB.RunInTransaction.
+	// Use TxReadOnly or TxWithReadTime to construct one.
+	TxOption datastore.TransactionOption
+
+	// TxQueryBuilder provides the same fluent query-building surface as
+	// QueryBuilder, but routes all reads and writes through a Tx so they
+	// participate in its transaction.
+	TxQueryBuilder[T any] struct {
+		context     context.Context
+		tx          *Tx
+		query       *datastore.Query
+		kind        string
+		namespace   string
+		limit       int
+		usingOffset bool
+		usingCursor bool
+		filter      Filter
+	}
+
+	// AggregationBuilder accumulates aggregations (count, sum, average) to
+	// run against a query's filters in a single RunAggregationQuery call.
+	// Obtain one via QueryBuilder.Aggregate.
+	AggregationBuilder struct {
+		context context.Context
+		db      *DB
+		query   *datastore.AggregationQuery
+	}
+
+	// AggregationResult holds the named results of an AggregationBuilder.Run
+	// call. Use Int64 for Count results and Float64 for Sum/Avg results.
+	AggregationResult map[string]interface{}
+
+	// StreamItem is a single entity (or error) delivered by
+	// QueryBuilder.Stream.
+	StreamItem[T any] struct {
+		Entity T
+		Err    error
+	}
+
+	// KeyPart names one level of a key or ancestor path: an entity kind and
+	// its string ID.
+	KeyPart struct {
+		Kind string
+		ID   string
+	}
+
+	// KeyBuilder constructs a chain of parent/child keys. Start one with
+	// NewKey and extend it with Child, then call Build to get the final
+	// *datastore.Key.
+	//
+	// A key built this way carries no namespace by default. If it will be
+	// used as a parent for a namespace-pinned QueryBuilder (one created with
+	// WithNamespace or ConnectWithNamespace), call Namespace with the same
+	// value first, or Datastore will reject the write/query: it requires a
+	// key and its parent to agree on namespace.
+	KeyBuilder struct {
+		parts     []KeyPart
+		namespace string
+	}
 )
 
 const (
@@ -105,14 +199,35 @@ const (
 //
 //	// Using explicit credentials
 //	db, err := dsx.Connect(ctx, "my-project", "my-db", credJSON)
-func Connect(ctx context.Context, projectId, databaseId, credentialsJSON string) (result *DB, err error) {
+func Connect(ctx context.Context, projectId, databaseId, credentialsJSON string) (*DB, error) {
+	return ConnectWithNamespace(ctx, projectId, databaseId, "", credentialsJSON)
+}
+
+// ConnectWithNamespace establishes a connection to Google Cloud Datastore
+// pinned to a single namespace, for multi-tenant workloads running on one
+// project. Every QueryBuilder created from the returned DB defaults to this
+// namespace; override it per-query with WithNamespace.
+//
+// Parameters:
+//   - ctx: Context for the connection
+//   - projectId: Google Cloud project ID
+//   - databaseId: Datastore database ID (use "" for default database)
+//   - namespace: Datastore namespace (use "" for the default namespace)
+//   - credentialsJSON: JSON credentials string (use "" to use default credentials)
+//
+// Returns a DB instance and any connection error.
+//
+// Example:
+//
+//	db, err := dsx.ConnectWithNamespace(ctx, "my-project", "", "tenant-42", "")
+func ConnectWithNamespace(ctx context.Context, projectId, databaseId, namespace, credentialsJSON string) (result *DB, err error) {
 	var client *datastore.Client
 	if credentialsJSON != "" {
 		client, err = datastore.NewClientWithDatabase(ctx, projectId, databaseId, option.WithCredentialsJSON([]byte(credentialsJSON)))
 	} else {
 		client, err = datastore.NewClientWithDatabase(ctx, projectId, databaseId)
 	}
-	return &DB{client: client, projectId: projectId, databaseId: databaseId}, err
+	return &DB{client: client, projectId: projectId, databaseId: databaseId, namespace: namespace}, err
 }
 
 // ProjectId returns the Google Cloud project ID for this connection.
@@ -125,12 +240,89 @@ func (db *DB) DatabaseId() string {
 	return db.databaseId
 }
 
+// Namespace returns the Datastore namespace this connection defaults new
+// queries to ("" for the default namespace).
+func (db *DB) Namespace() string {
+	return db.namespace
+}
+
+// WithNamespace returns a shallow copy of db pinned to namespace ns, so a
+// request-scoped tenant can be set once and reused across queries without
+// affecting the original DB.
+//
+// Example:
+//
+//	tenantDB := db.WithNamespace("tenant-42")
+//	users, err := dsx.Query[User](tenantDB, ctx, "User").Select()
+func (db *DB) WithNamespace(ns string) *DB {
+	clone := *db
+	clone.namespace = ns
+	return &clone
+}
+
 // Client returns the underlying datastore.Client for advanced operations
 // not covered by this wrapper.
 func (db *DB) Client() *datastore.Client {
 	return db.client
 }
 
+// TxReadOnly marks a transaction as read-only. Read-only transactions can
+// be more efficient than read-write ones when no writes are performed.
+func TxReadOnly() TxOption {
+	return TxOption(datastore.ReadOnly)
+}
+
+// TxWithReadTime runs a transaction against a consistent snapshot of
+// Datastore as of t, for historical or point-in-time reads.
+func TxWithReadTime(t time.Time) TxOption {
+	return TxOption(datastore.WithReadTime(t))
+}
+
+// RunInTransaction runs f inside a Datastore transaction, retrying on
+// contention as the underlying client.RunInTransaction does. Use TxQuery
+// inside f to read and write entities through tx so they participate in
+// the transaction's strong consistency guarantees.
+//
+// Pass TxReadOnly() for read-only transactions or TxWithReadTime to read a
+// consistent snapshot as of a past time.
+//
+// Returns the transaction's commit result, or an error if f returns one or
+// the commit fails.
+//
+// Example:
+//
+//	_, err := db.RunInTransaction(ctx, func(tx *dsx.Tx) error {
+//	    account, err := dsx.TxQuery[Account](tx, "Account").
+//	        WithFilter(dsx.FieldKey, dsx.OpEqual, "acct-1").
+//	        Get()
+//	    if err != nil || account == nil {
+//	        return err
+//	    }
+//	    account.Balance -= 100
+//	    return dsx.TxQuery[Account](tx, "Account").Upsert("acct-1", account)
+//	})
+func (db *DB) RunInTransaction(ctx context.Context, f func(tx *Tx) error, opts ...TxOption) (*datastore.Commit, error) {
+	dsOpts := make([]datastore.TransactionOption, len(opts))
+	for i, opt := range opts {
+		dsOpts[i] = datastore.TransactionOption(opt)
+	}
+
+	return db.client.RunInTransaction(ctx, func(dtx *datastore.Transaction) error {
+		return f(&Tx{tx: dtx, db: db, context: ctx})
+	}, dsOpts...)
+}
+
+// DB returns the database connection this transaction was started on.
+func (tx *Tx) DB() *DB {
+	return tx.db
+}
+
+// Transaction returns the underlying datastore.Transaction for advanced
+// operations not covered by TxQueryBuilder.
+func (tx *Tx) Transaction() *datastore.Transaction {
+	return tx.tx
+}
+
 // Query creates a new QueryBuilder for the specified entity kind.
 // The type parameter T specifies the Go struct type that entities will be
 // unmarshaled into.
@@ -147,16 +339,77 @@ func (db *DB) Client() *datastore.Client {
 //	    WithFilter("Status", dsx.OpEqual, "active").
 //	    Select()
 func Query[T any](db *DB, ctx context.Context, kind string) *QueryBuilder[T] {
+	query := datastore.NewQuery(kind)
+	if db.namespace != "" {
+		query = query.Namespace(db.namespace)
+	}
 	return &QueryBuilder[T]{
 		context:     ctx,
 		db:          db,
-		query:       datastore.NewQuery(kind),
+		query:       query,
 		kind:        kind,
+		namespace:   db.namespace,
 		usingOffset: false,
 		usingCursor: false,
 	}
 }
 
+// QueryAll creates a new kindless QueryBuilder, matching entities of any
+// kind. This is mainly useful for cross-kind scans filtered on FieldKey,
+// e.g. WithFilter(dsx.FieldKey, dsx.OpGreater, lastSeenKey).
+//
+// Example:
+//
+//	rows, err := dsx.QueryAll[datastore.PropertyList](db, ctx).
+//	    WithFilter(dsx.FieldKey, dsx.OpGreater, lastSeenKey).
+//	    WithLimit(100).
+//	    Select()
+func QueryAll[T any](db *DB, ctx context.Context) *QueryBuilder[T] {
+	return Query[T](db, ctx, "")
+}
+
+// NewKey starts a KeyBuilder for a top-level key with the given kind and
+// string ID. Chain Child to build an ancestor path, then call Build.
+//
+// Example:
+//
+//	key := dsx.NewKey("Company", "acme").Child("Employee", "e-1").Build()
+func NewKey(kind, id string) *KeyBuilder {
+	return &KeyBuilder{parts: []KeyPart{{Kind: kind, ID: id}}}
+}
+
+// Child extends the key path with another level, parented under the
+// preceding one.
+//
+// Returns the KeyBuilder for method chaining.
+func (kb *KeyBuilder) Child(kind, id string) *KeyBuilder {
+	kb.parts = append(kb.parts, KeyPart{Kind: kind, ID: id})
+	return kb
+}
+
+// Namespace sets the Datastore namespace applied to every level of the key
+// this KeyBuilder builds. Set it to match the QueryBuilder the key will be
+// used with (e.g. via WithNamespace or UpsertWithParent), since a key and
+// its parent must agree on namespace or Datastore rejects it.
+//
+// Returns the KeyBuilder for method chaining.
+func (kb *KeyBuilder) Namespace(ns string) *KeyBuilder {
+	kb.namespace = ns
+	return kb
+}
+
+// Build resolves the accumulated path into a *datastore.Key.
+func (kb *KeyBuilder) Build() *datastore.Key {
+	var key *datastore.Key
+	for _, part := range kb.parts {
+		key = datastore.NameKey(part.Kind, part.ID, key)
+		if kb.namespace != "" {
+			key.Namespace = kb.namespace
+		}
+	}
+	return key
+}
+
 // DB returns the database connection associated with this query.
 func (qb *QueryBuilder[T]) DB() *DB {
 	return qb.db
@@ -167,6 +420,32 @@ func (qb *QueryBuilder[T]) Kind() string {
 	return qb.kind
 }
 
+// WithNamespace overrides the Datastore namespace this query runs in,
+// regardless of the namespace the DB was connected with. It also applies
+// to keys this QueryBuilder constructs (Upsert, UpsertMulti, Delete, ...).
+//
+// Returns the QueryBuilder for method chaining.
+//
+// Example:
+//
+//	users, err := dsx.Query[User](db, ctx, "User").
+//	    WithNamespace("tenant-42").
+//	    Select()
+func (qb *QueryBuilder[T]) WithNamespace(ns string) *QueryBuilder[T] {
+	qb.namespace = ns
+	qb.query = qb.query.Namespace(ns)
+	return qb
+}
+
+// key builds a key for this query's kind and namespace.
+func (qb *QueryBuilder[T]) key(id string, parent *datastore.Key) *datastore.Key {
+	key := datastore.NameKey(qb.kind, id, parent)
+	if qb.namespace != "" {
+		key.Namespace = qb.namespace
+	}
+	return key
+}
+
 // WithDistinct marks the query to return only distinct results.
 // Typically used with projection queries.
 //
@@ -322,16 +601,93 @@ func (qb *QueryBuilder[T]) WithCursor(cursor string) *QueryBuilder[T] {
 //	    WithFilter("Status", dsx.OpIn, []string{"active", "pending"}).
 //	    Select()
 func (qb *QueryBuilder[T]) WithFilter(key string, operator FilterOperator, value interface{}) *QueryBuilder[T] {
-	if key == FieldKey {
-		if tmp, ok := value.(string); ok {
-			qb.query = qb.query.FilterField(key, string(operator), datastore.NameKey(qb.kind, tmp, nil))
-		}
+	qb.addFilter(qb.FilterBuilder().Property(key, operator, value))
+	return qb
+}
+
+// addFilter merges f into the query's root filter (AND-ing it with whatever
+// is already there). The tree is kept in qb.filter only; it is applied to
+// the underlying datastore.Query once, by effectiveQuery, right before the
+// query runs.
+func (qb *QueryBuilder[T]) addFilter(f Filter) {
+	if qb.filter == nil {
+		qb.filter = f
 	} else {
-		qb.query = qb.query.FilterField(key, string(operator), value)
+		qb.filter = datastore.AndFilter{Filters: []Filter{qb.filter, f}}
 	}
+}
+
+// effectiveQuery returns the datastore.Query to execute, with qb.filter (if
+// any) installed via a single FilterEntity call. datastore.Query.FilterEntity
+// appends to an internal list rather than replacing it, so qb.filter must be
+// applied exactly once per execution rather than re-applied on every
+// WithFilter/WithFilterEntity call.
+func (qb *QueryBuilder[T]) effectiveQuery() *datastore.Query {
+	if qb.filter == nil {
+		return qb.query
+	}
+	return qb.query.FilterEntity(qb.filter)
+}
+
+// FilterBuilder returns a builder for composing AND/OR filter trees scoped
+// to this query's kind. Filters produced by it are installed with
+// WithFilterEntity; see Filter for the resulting composite type.
+//
+// Example:
+//
+//	fb := dsx.Query[User](db, ctx, "User").FilterBuilder()
+//	users, err := dsx.Query[User](db, ctx, "User").
+//	    WithFilterEntity(fb.Or(
+//	        fb.Property("Status", dsx.OpEqual, "active"),
+//	        fb.Property("Status", dsx.OpEqual, "pending"),
+//	    )).
+//	    Select()
+func (qb *QueryBuilder[T]) FilterBuilder() *FilterBuilder {
+	return &FilterBuilder{kind: qb.kind, namespace: qb.namespace}
+}
+
+// WithFilterEntity installs f as the query's root filter, replacing any
+// filters previously added via WithFilter or WithFilterEntity.
+//
+// Returns the QueryBuilder for method chaining.
+func (qb *QueryBuilder[T]) WithFilterEntity(f Filter) *QueryBuilder[T] {
+	qb.filter = f
 	return qb
 }
 
+// Property builds a single comparison filter on field using operator and
+// value.
+//
+// When filtering by FieldKey ("__key__"), value may be either a string ID
+// (converted to a *datastore.Key) or an already-built *datastore.Key.
+func (fb *FilterBuilder) Property(field string, operator FilterOperator, value interface{}) Filter {
+	if field == FieldKey {
+		switch tmp := value.(type) {
+		case string:
+			key := datastore.NameKey(fb.kind, tmp, nil)
+			if fb.namespace != "" {
+				key.Namespace = fb.namespace
+			}
+			value = key
+		case *datastore.Key:
+			value = tmp
+		}
+	}
+	return datastore.PropertyFilter{FieldName: field, Operator: string(operator), Value: value}
+}
+
+// And combines children into a single filter that matches only entities
+// satisfying all of them.
+func (fb *FilterBuilder) And(children ...Filter) Filter {
+	return datastore.AndFilter{Filters: children}
+}
+
+// Or combines children into a single filter that matches entities
+// satisfying any of them.
+func (fb *FilterBuilder) Or(children ...Filter) Filter {
+	return datastore.OrFilter{Filters: children}
+}
+
 // WithAncestorKey filters the query to only return entities that are
 // descendants of the specified ancestor key. This enables strongly
 // consistent queries within an entity group.
@@ -353,6 +709,38 @@ func (qb *QueryBuilder[T]) WithAncestorKey(ancestorKey *datastore.Key) *QueryBui
 	return qb
 }
 
+// WithAncestorPath filters the query to only return entities that are
+// descendants of the key chain described by parts, without requiring
+// callers to build a *datastore.Key themselves. An empty parts is ignored.
+//
+// Every level of the resulting ancestor key is stamped with this query's
+// namespace (set via WithNamespace or the DB it was created from), so it
+// agrees with the namespace-scoped PartitionId the query itself is sent
+// with.
+//
+// Returns the QueryBuilder for method chaining.
+//
+// Example:
+//
+//	employees, err := dsx.Query[Employee](db, ctx, "Employee").
+//	    WithAncestorPath(dsx.KeyPart{Kind: "Company", ID: "acme"}).
+//	    Select()
+func (qb *QueryBuilder[T]) WithAncestorPath(parts ...KeyPart) *QueryBuilder[T] {
+	if len(parts) == 0 {
+		return qb
+	}
+
+	var ancestorKey *datastore.Key
+	for _, part := range parts {
+		ancestorKey = datastore.NameKey(part.Kind, part.ID, ancestorKey)
+		if qb.namespace != "" {
+			ancestorKey.Namespace = qb.namespace
+		}
+	}
+	qb.query = qb.query.Ancestor(ancestorKey)
+	return qb
+}
+
 // KeysOnly marks the query to return only entity keys, not full entities.
 // This is more efficient when you only need keys (e.g., for counting or
 // batch deletion).
@@ -365,6 +753,51 @@ func (qb *QueryBuilder[T]) KeysOnly() *QueryBuilder[T] {
 	return qb
 }
 
+// WithProjection restricts the query to only load the named fields,
+// which is more efficient than fetching full entities. Typically combined
+// with WithDistinct. Use SelectProjection to execute the query.
+//
+// Returns the QueryBuilder for method chaining.
+//
+// Example:
+//
+//	rows, err := dsx.Query[User](db, ctx, "User").
+//	    WithProjection("Status").
+//	    WithDistinct().
+//	    SelectProjection()
+func (qb *QueryBuilder[T]) WithProjection(fields ...string) *QueryBuilder[T] {
+	qb.query = qb.query.Project(fields...)
+	return qb
+}
+
+// SelectProjection executes a projection query and returns each matching
+// entity's loaded fields as a map of property name to value. Use this with
+// WithProjection instead of Select, since a projected entity generally
+// cannot be decoded into T.
+func (qb *QueryBuilder[T]) SelectProjection() ([]map[string]any, error) {
+	var result []map[string]any
+	it := qb.db.client.Run(qb.context, qb.effectiveQuery())
+	for {
+		var props datastore.PropertyList
+		_, err := it.Next(&props)
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			log.Println("datastore", qb.kind, "select-projection-error", err)
+			return nil, err
+		}
+
+		row := make(map[string]any, len(props))
+		for _, p := range props {
+			row[p.Name] = p.Value
+		}
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
 // Total returns the count of entities matching the current query filters.
 // It uses Datastore's aggregation query to efficiently count without loading entities into memory.
 //
@@ -377,20 +810,113 @@ func (qb *QueryBuilder[T]) KeysOnly() *QueryBuilder[T] {
 // Returns 0 and an error if the aggregation query fails or the count result is missing.
 // Note: Datastore count aggregations have a default limit of approximately 1 million entities.
 func (qb *QueryBuilder[T]) Total() (int64, error) {
-	aggQuery := qb.query.NewAggregationQuery().WithCount("total")
-	results, err := qb.db.client.RunAggregationQuery(qb.context, aggQuery)
+	result, err := qb.Aggregate().Count("total").Run()
+	if err != nil {
+		return 0, err
+	}
+	return result.Int64("total")
+}
+
+// Aggregate returns an AggregationBuilder for computing one or more
+// aggregations (count, sum, average) over the current query's filters in
+// a single RunAggregationQuery call, without materializing entities.
+//
+// Example:
+//
+//	result, err := dsx.Query[Order](db, ctx, "Order").
+//	    WithFilter("Status", dsx.OpEqual, "paid").
+//	    Aggregate().
+//	    Count("orders").
+//	    Sum("Total", "revenue").
+//	    Avg("Total", "avgOrder").
+//	    Run()
+//	orders, _ := result.Int64("orders")
+//	revenue, _ := result.Float64("revenue")
+func (qb *QueryBuilder[T]) Aggregate() *AggregationBuilder {
+	return &AggregationBuilder{
+		context: qb.context,
+		db:      qb.db,
+		query:   qb.effectiveQuery().NewAggregationQuery(),
+	}
+}
+
+// Count adds a count aggregation, available in the result under alias.
+//
+// Returns the AggregationBuilder for method chaining.
+func (ab *AggregationBuilder) Count(alias string) *AggregationBuilder {
+	ab.query = ab.query.WithCount(alias)
+	return ab
+}
+
+// Sum adds a sum-of-field aggregation, available in the result under alias.
+//
+// Returns the AggregationBuilder for method chaining.
+func (ab *AggregationBuilder) Sum(field, alias string) *AggregationBuilder {
+	ab.query = ab.query.WithSum(field, alias)
+	return ab
+}
+
+// Avg adds an average-of-field aggregation, available in the result under
+// alias.
+//
+// Returns the AggregationBuilder for method chaining.
+func (ab *AggregationBuilder) Avg(field, alias string) *AggregationBuilder {
+	ab.query = ab.query.WithAvg(field, alias)
+	return ab
+}
+
+// Run executes all aggregations added to this builder in a single
+// RunAggregationQuery call.
+func (ab *AggregationBuilder) Run() (AggregationResult, error) {
+	results, err := ab.db.client.RunAggregationQuery(ab.context, ab.query)
+	if err != nil {
+		return nil, err
+	}
+	return AggregationResult(results), nil
+}
+
+// Int64 returns the named result as an integer, as produced by Count.
+//
+// Returns an error if alias is missing or is not an integer result.
+func (r AggregationResult) Int64(alias string) (int64, error) {
+	val, err := r.value(alias)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := val.GetValueType().(*datastorepb.Value_IntegerValue)
+	if !ok {
+		return 0, fmt.Errorf("aggregation result %q is not an integer", alias)
+	}
+	return v.IntegerValue, nil
+}
+
+// Float64 returns the named result as a float, as produced by Sum or Avg.
+//
+// Returns an error if alias is missing or is not a numeric result.
+func (r AggregationResult) Float64(alias string) (float64, error) {
+	val, err := r.value(alias)
 	if err != nil {
 		return 0, err
 	}
-	count, ok := results["total"]
+	switch v := val.GetValueType().(type) {
+	case *datastorepb.Value_DoubleValue:
+		return v.DoubleValue, nil
+	case *datastorepb.Value_IntegerValue:
+		return float64(v.IntegerValue), nil
+	}
+	return 0, fmt.Errorf("aggregation result %q is not numeric", alias)
+}
+
+func (r AggregationResult) value(alias string) (*datastorepb.Value, error) {
+	raw, ok := r[alias]
 	if !ok {
-		return 0, errors.New("count result not found")
+		return nil, fmt.Errorf("aggregation result %q not found", alias)
 	}
-	val, ok := count.(*datastorepb.Value)
+	val, ok := raw.(*datastorepb.Value)
 	if !ok {
-		return 0, fmt.Errorf("unexpected count type: %T", count)
+		return nil, fmt.Errorf("unexpected aggregation value type: %T", raw)
 	}
-	return val.GetIntegerValue(), nil
+	return val, nil
 }
 
 // SelectWithCursor executes the query and returns results with a cursor
@@ -429,7 +955,7 @@ func (qb *QueryBuilder[T]) SelectWithCursor() ([]T, string, error) {
 	}
 
 	result := make([]T, 0, qb.limit)
-	it := qb.db.client.Run(qb.context, qb.query)
+	it := qb.db.client.Run(qb.context, qb.effectiveQuery())
 	for {
 		var entity T
 		_, err := it.Next(&entity)
@@ -471,7 +997,7 @@ func (qb *QueryBuilder[T]) Select() ([]T, error) {
 	}
 
 	var result []T
-	if _, err := qb.db.client.GetAll(qb.context, qb.query, &result); err != nil {
+	if _, err := qb.db.client.GetAll(qb.context, qb.effectiveQuery(), &result); err != nil {
 		log.Println("datastore", qb.kind, "select-error", err)
 		return nil, err
 	}
@@ -516,6 +1042,21 @@ func (qb *QueryBuilder[T]) Get() (*T, error) {
 	return nil, nil
 }
 
+// GetByKey fetches a single entity by its full key, bypassing the query's
+// filters. Returns nil (not an error) if the key does not exist.
+func (qb *QueryBuilder[T]) GetByKey(key *datastore.Key) (*T, error) {
+	var entity T
+	if err := qb.db.client.Get(qb.context, key, &entity); err != nil {
+		if errors.Is(err, datastore.ErrNoSuchEntity) {
+			return nil, nil
+		}
+		log.Println("datastore", qb.kind, "get-by-key-error", err)
+		return nil, err
+	}
+
+	return &entity, nil
+}
+
 // Upsert inserts or updates a single entity with the specified string ID.
 // If an entity with the ID exists, it is overwritten; otherwise, a new
 // entity is created.
@@ -529,7 +1070,7 @@ func (qb *QueryBuilder[T]) Get() (*T, error) {
 //	user := User{Name: "John", Email: "john@example.com", Status: "active"}
 //	err := dsx.Query[User](db, ctx, "User").Upsert("user-123", &user)
 func (qb *QueryBuilder[T]) Upsert(id string, data *T) error {
-	key := datastore.NameKey(qb.kind, id, nil)
+	key := qb.key(id, nil)
 	if _, err := qb.db.client.Put(qb.context, key, data); err != nil {
 		log.Println("datastore", qb.kind, "upsert-error", err)
 		return err
@@ -538,15 +1079,108 @@ func (qb *QueryBuilder[T]) Upsert(id string, data *T) error {
 	return nil
 }
 
-// UpsertMulti inserts or updates multiple entities in a single batch operation.
-// This is more efficient than calling Upsert multiple times.
+// UpsertWithParent inserts or updates a single entity with the specified
+// string ID, parented under parentKey (for example one built with NewKey).
+// Use this to write entities into an entity group rather than as top-level
+// keys; query them back with WithAncestorKey or WithAncestorPath.
+//
+// On a namespace-pinned QueryBuilder (WithNamespace or ConnectWithNamespace),
+// parentKey must carry the same namespace as this query, or Datastore
+// rejects the write as an invalid key. Build parentKey with
+// KeyBuilder.Namespace set to match.
+//
+// Example:
+//
+//	companyKey := dsx.NewKey("Company", "acme").Build()
+//	err := dsx.Query[Employee](db, ctx, "Employee").
+//	    UpsertWithParent(companyKey, "e-1", &employee)
+//
+//	// Namespace-pinned: the parent key's namespace must match the query's.
+//	companyKey := dsx.NewKey("Company", "acme").Namespace("tenant-42").Build()
+//	err := dsx.Query[Employee](db, ctx, "Employee").
+//	    WithNamespace("tenant-42").
+//	    UpsertWithParent(companyKey, "e-1", &employee)
+func (qb *QueryBuilder[T]) UpsertWithParent(parentKey *datastore.Key, id string, data *T) error {
+	key := qb.key(id, parentKey)
+	if _, err := qb.db.client.Put(qb.context, key, data); err != nil {
+		log.Println("datastore", qb.kind, "upsert-with-parent-error", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByKey removes a single entity by its full key, bypassing the
+// query's filters.
+func (qb *QueryBuilder[T]) DeleteByKey(key *datastore.Key) error {
+	if err := qb.db.client.Delete(qb.context, key); err != nil {
+		log.Println("datastore", qb.kind, "delete-by-key-error", err)
+		return err
+	}
+
+	return nil
+}
+
+// maxBatchSize is Datastore's limit on entities per Put/Delete-multi call.
+// maxBatchConcurrency bounds how many such batches run at once.
+const (
+	maxBatchSize        = 500
+	maxBatchConcurrency = 8
+)
+
+// runBatched splits total items into chunks of at most maxBatchSize and
+// runs fn, which operates on the half-open range [start, end), concurrently
+// across a worker pool bounded by maxBatchConcurrency. Errors from every
+// batch are aggregated with errors.Join rather than aborting early.
+func runBatched(total int, fn func(start, end int) error) error {
+	if total == 0 {
+		return nil
+	}
+
+	batches := (total + maxBatchSize - 1) / maxBatchSize
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxBatchConcurrency)
+	errCh := make(chan error, batches)
+
+	for start := 0; start < total; start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > total {
+			end = total
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(start, end); err != nil {
+				errCh <- err
+			}
+		}(start, end)
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// UpsertMulti inserts or updates multiple entities, automatically splitting
+// them into Datastore's 500-entity batch limit and running the batches
+// concurrently. This is more efficient than calling Upsert multiple times
+// or chunking manually.
 //
 // Parameters:
 //   - items: Map of string ID to entity pointer
 //
-// Note: Datastore has a limit of 500 entities per batch operation.
-// For larger batches, split into multiple calls.
-//
 // Example:
 //
 //	users := map[string]*User{
@@ -562,20 +1196,48 @@ func (qb *QueryBuilder[T]) UpsertMulti(items map[string]*T) error {
 	keys := make([]*datastore.Key, 0, len(items))
 	entities := make([]*T, 0, len(items))
 	for id, data := range items {
-		keys = append(keys, datastore.NameKey(qb.kind, id, nil))
+		keys = append(keys, qb.key(id, nil))
 		entities = append(entities, data)
 	}
 
-	if _, err := qb.db.client.PutMulti(qb.context, keys, entities); err != nil {
-		log.Println("datastore", qb.kind, "upsert-multi-error", err)
-		return err
+	return runBatched(len(keys), func(start, end int) error {
+		if _, err := qb.db.client.PutMulti(qb.context, keys[start:end], entities[start:end]); err != nil {
+			log.Println("datastore", qb.kind, "upsert-multi-error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteMulti removes multiple entities by string ID, automatically
+// splitting them into Datastore's 500-entity batch limit and running the
+// batches concurrently.
+//
+// Example:
+//
+//	err := dsx.Query[User](db, ctx, "User").DeleteMulti("user-1", "user-2")
+func (qb *QueryBuilder[T]) DeleteMulti(ids ...string) error {
+	if len(ids) == 0 {
+		return nil
 	}
 
-	return nil
+	keys := make([]*datastore.Key, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, qb.key(id, nil))
+	}
+
+	return runBatched(len(keys), func(start, end int) error {
+		if err := qb.db.client.DeleteMulti(qb.context, keys[start:end]); err != nil {
+			log.Println("datastore", qb.kind, "delete-multi-error", err)
+			return err
+		}
+		return nil
+	})
 }
 
-// Delete removes all entities matching the current query filters.
-// Entities are deleted in batches of 500 (Datastore's limit per operation).
+// Delete removes all entities matching the current query filters,
+// automatically splitting them into Datastore's 500-entity batch limit and
+// running the batches concurrently.
 //
 // Warning: Without filters, this will delete ALL entities of the kind.
 // Use with caution.
@@ -591,26 +1253,428 @@ func (qb *QueryBuilder[T]) UpsertMulti(items map[string]*T) error {
 //	err := dsx.Query[User](db, ctx, "User").
 //	    WithFilter(dsx.FieldKey, dsx.OpEqual, "user-123").
 //	    Delete()
-func (qb *QueryBuilder[T]) Delete() (err error) {
-	keys, err := qb.db.client.GetAll(qb.context, qb.query.KeysOnly(), nil)
+func (qb *QueryBuilder[T]) Delete() error {
+	keys, err := qb.db.client.GetAll(qb.context, qb.effectiveQuery().KeysOnly(), nil)
 	if err != nil {
 		log.Println("datastore", qb.kind, "delete", "get-all", "error", err)
 		return err
 	}
-	totalKey := len(keys)
-	if totalKey > 0 {
-		for i := 0; i < totalKey; i += 500 {
-			end := i + 500
-			if end > totalKey {
-				end = totalKey
+
+	return runBatched(len(keys), func(start, end int) error {
+		if err := qb.db.client.DeleteMulti(qb.context, keys[start:end]); err != nil {
+			log.Println("datastore", qb.kind, "delete", "delete-multi", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// Stream runs the query and emits each decoded entity on the returned
+// channel as it arrives from Datastore, without materializing the full
+// result set in memory. The channel is closed when the query is exhausted,
+// ctx is done, or an error occurs (delivered as the final StreamItem).
+//
+// The returned cursor accessor reflects the iterator's position after the
+// most recently received item; call it once consumption is finished (or
+// paused) to resume later with WithCursor.
+//
+// Example:
+//
+//	items, cursor := dsx.Query[Event](db, ctx, "Event").Stream(ctx)
+//	for item := range items {
+//	    if item.Err != nil {
+//	        return item.Err
+//	    }
+//	    process(item.Entity)
+//	}
+//	resumeFrom := cursor()
+func (qb *QueryBuilder[T]) Stream(ctx context.Context) (<-chan StreamItem[T], func() string) {
+	ch := make(chan StreamItem[T])
+	it := qb.db.client.Run(ctx, qb.effectiveQuery())
+
+	// it is a *datastore.Iterator, which the datastore package documents as
+	// not safe for concurrent use. The goroutine records it.Cursor() in
+	// lastCursor only after an item has been handed to the receiver, so the
+	// accessor below never reports a position past an item not yet received;
+	// itMu guards lastCursor.
+	var itMu sync.Mutex
+	var lastCursor string
+
+	go func() {
+		defer close(ch)
+		for {
+			itMu.Lock()
+			var entity T
+			_, err := it.Next(&entity)
+			itMu.Unlock()
+			if errors.Is(err, iterator.Done) {
+				return
+			}
+
+			item := StreamItem[T]{Entity: entity, Err: err}
+			if err != nil {
+				log.Println("datastore", qb.kind, "stream-error", err)
 			}
 
-			batch := keys[i:end]
-			if err = qb.db.client.DeleteMulti(qb.context, batch); err != nil {
-				log.Println("datastore", qb.kind, "delete", "delete-multi", "error", err)
-				return err
+			select {
+			case ch <- item:
+			case <-ctx.Done():
+				return
 			}
+
+			if err != nil {
+				return
+			}
+
+			if cursor, err := it.Cursor(); err == nil {
+				itMu.Lock()
+				lastCursor = cursor.String()
+				itMu.Unlock()
+			}
+		}
+	}()
+
+	return ch, func() string {
+		itMu.Lock()
+		defer itMu.Unlock()
+		return lastCursor
+	}
+}
+
+// TxQuery creates a new TxQueryBuilder for the specified entity kind,
+// scoped to tx. It mirrors Query, but every read and write it performs is
+// part of tx's transaction.
+//
+// Example:
+//
+//	_, err := db.RunInTransaction(ctx, func(tx *dsx.Tx) error {
+//	    users, err := dsx.TxQuery[User](tx, "User").
+//	        WithFilter("Status", dsx.OpEqual, "active").
+//	        Select()
+//	    ...
+//	})
+func TxQuery[T any](tx *Tx, kind string) *TxQueryBuilder[T] {
+	query := datastore.NewQuery(kind)
+	if tx.db.namespace != "" {
+		query = query.Namespace(tx.db.namespace)
+	}
+	return &TxQueryBuilder[T]{
+		context:   tx.context,
+		tx:        tx,
+		query:     query,
+		kind:      kind,
+		namespace: tx.db.namespace,
+	}
+}
+
+// DB returns the database connection associated with this query.
+func (qb *TxQueryBuilder[T]) DB() *DB {
+	return qb.tx.db
+}
+
+// Kind returns the entity kind (table name) being queried.
+func (qb *TxQueryBuilder[T]) Kind() string {
+	return qb.kind
+}
+
+// WithDistinct marks the query to return only distinct results.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithDistinct() *TxQueryBuilder[T] {
+	qb.query = qb.query.Distinct()
+	return qb
+}
+
+// WithLimit sets the maximum number of entities to return.
+// A limit of 0 or negative is ignored.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithLimit(limit int) *TxQueryBuilder[T] {
+	if limit > 0 {
+		qb.query = qb.query.Limit(limit)
+		qb.limit = limit
+	}
+	return qb
+}
+
+// WithOffset sets the number of entities to skip before returning results.
+// An offset of 0 or negative is ignored.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithOffset(offset int) *TxQueryBuilder[T] {
+	if offset > 0 {
+		qb.query = qb.query.Offset(offset)
+		qb.usingOffset = true
+	}
+	return qb
+}
+
+// WithOrder adds an ascending sort order on the specified field.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithOrder(field string) *TxQueryBuilder[T] {
+	qb.query = qb.query.Order(field)
+	return qb
+}
+
+// WithOrderDesc adds a descending sort order on the specified field.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithOrderDesc(field string) *TxQueryBuilder[T] {
+	qb.query = qb.query.Order("-" + field)
+	return qb
+}
+
+// WithCursor sets the starting point for cursor-based pagination. See
+// QueryBuilder.WithCursor for details.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithCursor(cursor string) *TxQueryBuilder[T] {
+	if cursor != "" {
+		c, err := datastore.DecodeCursor(cursor)
+		if err == nil {
+			qb.query = qb.query.Start(c)
+			qb.usingCursor = true
+		}
+	}
+	return qb
+}
+
+// WithFilter adds a filter condition to the query. See QueryBuilder.WithFilter
+// for details; behaves identically but scoped to the transaction.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithFilter(key string, operator FilterOperator, value interface{}) *TxQueryBuilder[T] {
+	qb.addFilter(qb.FilterBuilder().Property(key, operator, value))
+	return qb
+}
+
+// addFilter merges f into the query's root filter, mirroring
+// QueryBuilder.addFilter. The tree is kept in qb.filter only; it is applied
+// to the underlying datastore.Query once, by effectiveQuery, right before
+// the query runs.
+func (qb *TxQueryBuilder[T]) addFilter(f Filter) {
+	if qb.filter == nil {
+		qb.filter = f
+	} else {
+		qb.filter = datastore.AndFilter{Filters: []Filter{qb.filter, f}}
+	}
+}
+
+// effectiveQuery returns the datastore.Query to execute, with qb.filter (if
+// any) installed via a single FilterEntity call, mirroring
+// QueryBuilder.effectiveQuery.
+func (qb *TxQueryBuilder[T]) effectiveQuery() *datastore.Query {
+	if qb.filter == nil {
+		return qb.query
+	}
+	return qb.query.FilterEntity(qb.filter)
+}
+
+// FilterBuilder returns a builder for composing AND/OR filter trees scoped
+// to this query's kind. See QueryBuilder.FilterBuilder for details.
+func (qb *TxQueryBuilder[T]) FilterBuilder() *FilterBuilder {
+	return &FilterBuilder{kind: qb.kind, namespace: qb.namespace}
+}
+
+// WithFilterEntity installs f as the query's root filter, replacing any
+// filters previously added via WithFilter or WithFilterEntity.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithFilterEntity(f Filter) *TxQueryBuilder[T] {
+	qb.filter = f
+	return qb
+}
+
+// WithAncestorKey filters the query to only return entities that are
+// descendants of the specified ancestor key. A nil ancestor key is ignored.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) WithAncestorKey(ancestorKey *datastore.Key) *TxQueryBuilder[T] {
+	if ancestorKey != nil {
+		qb.query = qb.query.Ancestor(ancestorKey)
+	}
+	return qb
+}
+
+// KeysOnly marks the query to return only entity keys, not full entities.
+//
+// Returns the TxQueryBuilder for method chaining.
+func (qb *TxQueryBuilder[T]) KeysOnly() *TxQueryBuilder[T] {
+	qb.query = qb.query.KeysOnly()
+	return qb
+}
+
+// SelectWithCursor executes the query within the transaction and returns
+// results with a cursor for pagination. See QueryBuilder.SelectWithCursor
+// for details.
+//
+// Returns an error if the query was configured with WithOffset.
+func (qb *TxQueryBuilder[T]) SelectWithCursor() ([]T, string, error) {
+	if qb.usingOffset {
+		return nil, "", errors.New("query defined to use offset instead of cursor")
+	}
+
+	result := make([]T, 0, qb.limit)
+	it := qb.tx.db.client.Run(qb.context, qb.effectiveQuery().Transaction(qb.tx.tx))
+	for {
+		var entity T
+		_, err := it.Next(&entity)
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			log.Println("datastore", qb.kind, "tx-select-error", err)
+			return nil, "", err
+		}
+		result = append(result, entity)
+	}
+
+	cursor, err := it.Cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return result, cursor.String(), nil
+}
+
+// Select executes the query within the transaction and returns all
+// matching entities.
+//
+// Returns an error if the query was configured with WithCursor.
+func (qb *TxQueryBuilder[T]) Select() ([]T, error) {
+	if qb.usingCursor {
+		return nil, errors.New("query defined to use cursor")
+	}
+
+	var result []T
+	it := qb.tx.db.client.Run(qb.context, qb.effectiveQuery().Transaction(qb.tx.tx))
+	for {
+		var entity T
+		_, err := it.Next(&entity)
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			log.Println("datastore", qb.kind, "tx-select-error", err)
+			return nil, err
+		}
+		result = append(result, entity)
+	}
+
+	return result, nil
+}
+
+// Get executes the query within the transaction and returns the first
+// matching entity. Returns nil (not an error) if no entities match.
+//
+// Returns an error if the query was configured with WithCursor.
+func (qb *TxQueryBuilder[T]) Get() (*T, error) {
+	if qb.usingCursor {
+		return nil, errors.New("query defined to use cursor")
+	}
+
+	tmp, err := qb.Select()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tmp) > 0 {
+		return &tmp[0], nil
+	}
+
+	return nil, nil
+}
+
+// GetByKey fetches a single entity by key within the transaction.
+// Returns nil (not an error) if the key does not exist.
+func (qb *TxQueryBuilder[T]) GetByKey(key *datastore.Key) (*T, error) {
+	var entity T
+	if err := qb.tx.tx.Get(key, &entity); err != nil {
+		if errors.Is(err, datastore.ErrNoSuchEntity) {
+			return nil, nil
 		}
+		log.Println("datastore", qb.kind, "tx-get-error", err)
+		return nil, err
+	}
+
+	return &entity, nil
+}
+
+// key builds a key for this query's kind and namespace.
+func (qb *TxQueryBuilder[T]) key(id string) *datastore.Key {
+	key := datastore.NameKey(qb.kind, id, nil)
+	if qb.namespace != "" {
+		key.Namespace = qb.namespace
+	}
+	return key
+}
+
+// Upsert inserts or updates a single entity with the specified string ID,
+// as part of the transaction.
+func (qb *TxQueryBuilder[T]) Upsert(id string, data *T) error {
+	key := qb.key(id)
+	if _, err := qb.tx.tx.Put(key, data); err != nil {
+		log.Println("datastore", qb.kind, "tx-upsert-error", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpsertMulti inserts or updates multiple entities in a single batch, as
+// part of the transaction.
+//
+// Note: Datastore has a limit of 500 entities per batch operation.
+func (qb *TxQueryBuilder[T]) UpsertMulti(items map[string]*T) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]*datastore.Key, 0, len(items))
+	entities := make([]*T, 0, len(items))
+	for id, data := range items {
+		keys = append(keys, qb.key(id))
+		entities = append(entities, data)
+	}
+
+	if _, err := qb.tx.tx.PutMulti(keys, entities); err != nil {
+		log.Println("datastore", qb.kind, "tx-upsert-multi-error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the entity with the specified string ID, as part of the
+// transaction.
+func (qb *TxQueryBuilder[T]) Delete(id string) error {
+	key := qb.key(id)
+	if err := qb.tx.tx.Delete(key); err != nil {
+		log.Println("datastore", qb.kind, "tx-delete-error", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteMulti removes multiple entities by string ID in a single batch, as
+// part of the transaction.
+//
+// Note: Datastore has a limit of 500 entities per batch operation.
+func (qb *TxQueryBuilder[T]) DeleteMulti(ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	keys := make([]*datastore.Key, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, qb.key(id))
+	}
+
+	if err := qb.tx.tx.DeleteMulti(keys); err != nil {
+		log.Println("datastore", qb.kind, "tx-delete-multi-error", err)
+		return err
 	}
 
 	return nil
@@ -0,0 +1,181 @@
+package dsx
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"cloud.google.com/go/datastore/apiv1/datastorepb"
+)
+
+func TestAggregationResultInt64(t *testing.T) {
+	result := AggregationResult{
+		"total": &datastorepb.Value{ValueType: &datastorepb.Value_IntegerValue{IntegerValue: 42}},
+	}
+
+	got, err := result.Int64("total")
+	if err != nil {
+		t.Fatalf("Int64 returned error: %v", err)
+	}
+	if got != 42 {
+		t.Errorf("Int64 = %d, want 42", got)
+	}
+
+	if _, err := result.Int64("missing"); err == nil {
+		t.Error("Int64 on missing alias: want error, got nil")
+	}
+}
+
+func TestAggregationResultFloat64(t *testing.T) {
+	result := AggregationResult{
+		"avgOrder": &datastorepb.Value{ValueType: &datastorepb.Value_DoubleValue{DoubleValue: 19.99}},
+	}
+
+	got, err := result.Float64("avgOrder")
+	if err != nil {
+		t.Fatalf("Float64 returned error: %v", err)
+	}
+	if got != 19.99 {
+		t.Errorf("Float64 = %v, want 19.99", got)
+	}
+
+	if _, err := result.Float64("missing"); err == nil {
+		t.Error("Float64 on missing alias: want error, got nil")
+	}
+}
+
+func TestAggregationResultValueWrongType(t *testing.T) {
+	result := AggregationResult{"total": "not-a-proto-value"}
+
+	if _, err := result.value("total"); err == nil {
+		t.Error("value with unexpected type: want error, got nil")
+	}
+}
+
+func TestFilterBuilderProperty(t *testing.T) {
+	fb := &FilterBuilder{kind: "User"}
+
+	f := fb.Property("Status", OpEqual, "active")
+	pf, ok := f.(datastore.PropertyFilter)
+	if !ok {
+		t.Fatalf("Property returned %T, want datastore.PropertyFilter", f)
+	}
+	if pf.FieldName != "Status" || pf.Operator != "=" || pf.Value != "active" {
+		t.Errorf("Property = %+v, want FieldName=Status Operator= Value=active", pf)
+	}
+}
+
+func TestFilterBuilderPropertyFieldKey(t *testing.T) {
+	fb := &FilterBuilder{kind: "User", namespace: "tenant-42"}
+
+	f := fb.Property(FieldKey, OpEqual, "user-123")
+	pf, ok := f.(datastore.PropertyFilter)
+	if !ok {
+		t.Fatalf("Property returned %T, want datastore.PropertyFilter", f)
+	}
+	key, ok := pf.Value.(*datastore.Key)
+	if !ok {
+		t.Fatalf("Property(FieldKey, ...) value = %T, want *datastore.Key", pf.Value)
+	}
+	if key.Kind != "User" || key.Name != "user-123" || key.Namespace != "tenant-42" {
+		t.Errorf("key = %+v, want Kind=User Name=user-123 Namespace=tenant-42", key)
+	}
+}
+
+func TestFilterBuilderAndOr(t *testing.T) {
+	fb := &FilterBuilder{kind: "User"}
+	a := fb.Property("Status", OpEqual, "active")
+	b := fb.Property("Status", OpEqual, "pending")
+
+	or, ok := fb.Or(a, b).(datastore.OrFilter)
+	if !ok || len(or.Filters) != 2 {
+		t.Fatalf("Or = %+v, want OrFilter with 2 children", or)
+	}
+
+	and, ok := fb.And(a, b).(datastore.AndFilter)
+	if !ok || len(and.Filters) != 2 {
+		t.Fatalf("And = %+v, want AndFilter with 2 children", and)
+	}
+}
+
+func TestKeyBuilderNamespace(t *testing.T) {
+	key := NewKey("Company", "acme").Namespace("tenant-42").Child("Employee", "e1").Build()
+
+	if key.Namespace != "tenant-42" {
+		t.Errorf("child key Namespace = %q, want %q", key.Namespace, "tenant-42")
+	}
+	if key.Parent == nil || key.Parent.Namespace != "tenant-42" {
+		t.Errorf("parent key Namespace = %q, want %q", key.Parent.Namespace, "tenant-42")
+	}
+}
+
+func TestDBWithNamespace(t *testing.T) {
+	db := &DB{projectId: "proj", namespace: "default"}
+
+	tenantDB := db.WithNamespace("tenant-42")
+
+	if tenantDB == db {
+		t.Fatal("WithNamespace returned the same *DB, want a distinct clone")
+	}
+	if tenantDB.Namespace() != "tenant-42" {
+		t.Errorf("tenantDB.Namespace() = %q, want %q", tenantDB.Namespace(), "tenant-42")
+	}
+	if db.Namespace() != "default" {
+		t.Errorf("original db.Namespace() = %q, want unchanged %q", db.Namespace(), "default")
+	}
+}
+
+func TestRunBatchedEmpty(t *testing.T) {
+	called := false
+	if err := runBatched(0, func(start, end int) error {
+		called = true
+		return nil
+	}); err != nil {
+		t.Fatalf("runBatched(0, ...) returned error: %v", err)
+	}
+	if called {
+		t.Error("runBatched(0, ...) invoked fn, want no calls")
+	}
+}
+
+func TestRunBatchedChunking(t *testing.T) {
+	var calls int32
+	var totalCovered int32
+
+	err := runBatched(1200, func(start, end int) error {
+		atomic.AddInt32(&calls, 1)
+		if end-start > maxBatchSize {
+			t.Errorf("batch [%d,%d) has size %d, want <= %d", start, end, end-start, maxBatchSize)
+		}
+		atomic.AddInt32(&totalCovered, int32(end-start))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runBatched returned error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3 batches for 1200 items", calls)
+	}
+	if totalCovered != 1200 {
+		t.Errorf("totalCovered = %d, want 1200", totalCovered)
+	}
+}
+
+func TestRunBatchedAggregatesErrors(t *testing.T) {
+	errA := errors.New("batch a failed")
+	errB := errors.New("batch b failed")
+
+	err := runBatched(maxBatchSize*2, func(start, end int) error {
+		if start == 0 {
+			return errA
+		}
+		return errB
+	})
+	if err == nil {
+		t.Fatal("runBatched: want aggregated error, got nil")
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("runBatched error = %v, want it to wrap both batch errors", err)
+	}
+}